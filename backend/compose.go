@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/compose-spec/compose-go/loader"
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/labstack/echo/v4"
+)
+
+const composeProjectLabel = "mongodb-atlas-local.project"
+
+// composeStack provisions the Atlas Local containers described by a
+// docker-compose.yml (or equivalent JSON) document, labelling every
+// resource it creates with the compose project name so the stack can be
+// listed and torn down as a unit later.
+func composeStack(ctx echo.Context) error {
+	raw, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	project, err := loader.Load(composetypes.ConfigDetails{
+		ConfigFiles: []composetypes.ConfigFile{
+			{Filename: "docker-compose.yml", Content: raw},
+		},
+	}, func(options *loader.Options) {
+		options.SkipValidation = true
+	})
+	if err != nil {
+		return err
+	}
+
+	projectName := project.Name
+	if projectName == "" {
+		projectName = ctx.QueryParam("project")
+	}
+	if projectName == "" {
+		return fmt.Errorf("compose project name is required: set name in the document or pass the project query parameter")
+	}
+
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+
+	containerCtx := context.Background()
+	labels := map[string]string{composeProjectLabel: projectName}
+
+	created := []string{}
+	createdNetworks := []string{}
+	rollback := func() {
+		for _, id := range created {
+			_ = c.ContainerRemove(containerCtx, id, types.ContainerRemoveOptions{Force: true})
+		}
+		for _, id := range createdNetworks {
+			_ = c.NetworkRemove(containerCtx, id)
+		}
+	}
+
+	services := orderedByDependsOn(project.Services)
+
+	for _, service := range services {
+		containerName := fmt.Sprintf("%s-%s", projectName, service.Name)
+
+		env := []string{}
+		for k, v := range service.Environment {
+			if v != nil {
+				env = append(env, fmt.Sprintf("%s=%s", k, *v))
+			}
+		}
+
+		mounts, err := composeVolumeMounts(containerCtx, c, service, labels)
+		if err != nil {
+			rollback()
+			return err
+		}
+
+		endpoints, networkIDs, err := composeServiceNetworks(containerCtx, c, projectName, service, labels)
+		if err != nil {
+			rollback()
+			return err
+		}
+		createdNetworks = append(createdNetworks, networkIDs...)
+
+		portBindings := nat.PortMap{}
+		for _, p := range service.Ports {
+			portBindings[nat.Port(fmt.Sprintf("%d/tcp", p.Target))] = []nat.PortBinding{
+				{HostIP: cfg.HostBindIP, HostPort: p.Published},
+			}
+		}
+
+		// The container-create API only accepts a single network; any
+		// additional networks the service belongs to are attached with
+		// NetworkConnect once the container exists.
+		primary := map[string]*network.EndpointSettings{}
+		extra := map[string]*network.EndpointSettings{}
+		for name, endpoint := range endpoints {
+			if len(primary) == 0 {
+				primary[name] = endpoint
+				continue
+			}
+			extra[name] = endpoint
+		}
+
+		result, err := c.ContainerCreate(containerCtx, &container.Config{
+			Hostname: service.Name,
+			Image:    cfg.Image(),
+			Env:      env,
+			Labels:   labels,
+		}, &container.HostConfig{
+			PortBindings: portBindings,
+			Mounts:       mounts,
+		}, &network.NetworkingConfig{
+			EndpointsConfig: primary,
+		}, nil, containerName)
+		if err != nil {
+			rollback()
+			return err
+		}
+		created = append(created, result.ID)
+
+		for name, endpoint := range extra {
+			if err := c.NetworkConnect(containerCtx, name, result.ID, endpoint); err != nil {
+				rollback()
+				return err
+			}
+		}
+
+		if err := c.ContainerStart(containerCtx, result.ID, types.ContainerStartOptions{}); err != nil {
+			rollback()
+			return err
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Compose stack created", "project": projectName})
+}
+
+// composeServiceNetworks resolves the Docker networks a compose service
+// belongs to, creating each one (scoped to the project) if it doesn't
+// exist yet. A service with no explicit `networks:` falls back to the
+// project's shared default network.
+func composeServiceNetworks(ctx context.Context, c *client.Client, projectName string, service composetypes.ServiceConfig, labels map[string]string) (map[string]*network.EndpointSettings, []string, error) {
+	endpoints := map[string]*network.EndpointSettings{}
+	created := []string{}
+
+	names := []string{}
+	if len(service.Networks) == 0 {
+		names = append(names, "default")
+	} else {
+		for name := range service.Networks {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		networkName := fmt.Sprintf("%s_%s", projectName, name)
+		networkID, wasCreated, err := ensureComposeNetwork(ctx, c, networkName, labels)
+		if err != nil {
+			return nil, nil, err
+		}
+		endpoints[networkName] = &network.EndpointSettings{NetworkID: networkID}
+		if wasCreated {
+			created = append(created, networkID)
+		}
+	}
+
+	return endpoints, created, nil
+}
+
+// orderedByDependsOn returns services in an order that satisfies
+// depends_on, so dependencies are started before their dependents.
+func orderedByDependsOn(services composetypes.Services) composetypes.Services {
+	byName := map[string]composetypes.ServiceConfig{}
+	for _, s := range services {
+		byName[s.Name] = s
+	}
+
+	visited := map[string]bool{}
+	ordered := composetypes.Services{}
+
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		service, ok := byName[name]
+		if !ok {
+			return
+		}
+		for dep := range service.DependsOn {
+			visit(dep)
+		}
+		ordered = append(ordered, service)
+	}
+
+	names := make([]string, 0, len(services))
+	for _, s := range services {
+		names = append(names, s.Name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		visit(name)
+	}
+
+	return ordered
+}
+
+func composeVolumeMounts(ctx context.Context, c *client.Client, service composetypes.ServiceConfig, labels map[string]string) ([]mount.Mount, error) {
+	mounts := []mount.Mount{}
+
+	for _, v := range service.Volumes {
+		switch v.Type {
+		case composetypes.VolumeTypeBind:
+			mounts = append(mounts, mount.Mount{
+				Type:   mount.TypeBind,
+				Source: v.Source,
+				Target: v.Target,
+			})
+		case composetypes.VolumeTypeVolume:
+			volumeName := fmt.Sprintf("%s-%s", labels[composeProjectLabel], v.Source)
+			if _, err := c.VolumeInspect(ctx, volumeName); err != nil {
+				if _, err := c.VolumeCreate(ctx, volume.CreateOptions{
+					Name:   volumeName,
+					Labels: labels,
+				}); err != nil {
+					return nil, err
+				}
+			}
+			mounts = append(mounts, mount.Mount{
+				Type:   mount.TypeVolume,
+				Source: volumeName,
+				Target: v.Target,
+			})
+		}
+	}
+
+	return mounts, nil
+}
+
+// ensureComposeNetwork returns the ID of the named network, creating it if
+// it doesn't exist yet. The second return value reports whether this call
+// created the network, so a caller rolling back a failed request only
+// removes networks it actually created.
+func ensureComposeNetwork(ctx context.Context, c *client.Client, name string, labels map[string]string) (string, bool, error) {
+	networks, err := c.NetworkList(ctx, types.NetworkListOptions{
+		Filters: filters.NewArgs(filters.Arg("name", name)),
+	})
+	if err != nil {
+		return "", false, err
+	}
+	for _, n := range networks {
+		if n.Name == name {
+			return n.ID, false, nil
+		}
+	}
+
+	created, err := c.NetworkCreate(ctx, name, types.NetworkCreate{Labels: labels})
+	if err != nil {
+		return "", false, err
+	}
+	return created.ID, true, nil
+}
+
+func listComposeStack(ctx echo.Context) error {
+	project := ctx.Param("project")
+
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+
+	containers, err := c.ContainerList(context.Background(), types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", composeProjectLabel, project))),
+	})
+	if err != nil {
+		return err
+	}
+
+	response := []ListContainersResponse{}
+	for _, cnt := range containers {
+		name := cnt.ID[:12]
+		if len(cnt.Names) > 0 && cnt.Names[0] != "" {
+			name = cnt.Names[0][1:]
+		}
+		response = append(response, ListContainersResponse{
+			Id:     cnt.ID,
+			Name:   name,
+			Status: cnt.Status,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// deleteComposeStack tears down every container, network, and volume
+// labelled with the given compose project, continuing on individual
+// failures so a partially-removed stack doesn't get stuck.
+func deleteComposeStack(ctx echo.Context) error {
+	project := ctx.Param("project")
+	labelFilter := filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", composeProjectLabel, project)))
+
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+
+	containerCtx := context.Background()
+	var errs []error
+
+	containers, err := c.ContainerList(containerCtx, types.ContainerListOptions{All: true, Filters: labelFilter})
+	if err != nil {
+		return err
+	}
+	for _, cnt := range containers {
+		if err := c.ContainerRemove(containerCtx, cnt.ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	volumes, err := c.VolumeList(containerCtx, volume.ListOptions{Filters: labelFilter})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, v := range volumes.Volumes {
+		if err := c.VolumeRemove(containerCtx, v.Name, true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	networks, err := c.NetworkList(containerCtx, types.NetworkListOptions{Filters: labelFilter})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, n := range networks {
+		if err := c.NetworkRemove(containerCtx, n.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Compose stack removed"})
+}
@@ -0,0 +1,100 @@
+// Package config loads backend settings from ATLAS_LOCAL_* environment
+// variables, optionally overridden by a YAML file, so operators can harden
+// defaults (host bind IP, allowed image tags) without code changes.
+package config
+
+import (
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the backend's runtime settings.
+type Config struct {
+	DefaultImage    string   `yaml:"defaultImage"`
+	DefaultTag      string   `yaml:"defaultTag"`
+	DefaultUsername string   `yaml:"defaultUsername"`
+	DefaultPassword string   `yaml:"defaultPassword"`
+	HostBindIP      string   `yaml:"hostBindIP"`
+	LogLevel        string   `yaml:"logLevel"`
+	AllowedLabels   []string `yaml:"allowedLabels"`
+	AllowedTags     []string `yaml:"allowedTags"`
+}
+
+// Default returns the configuration used when no env vars or config file
+// are supplied.
+func Default() *Config {
+	return &Config{
+		DefaultImage:  "mongodb/mongodb-atlas-local",
+		DefaultTag:    "latest",
+		HostBindIP:    "0.0.0.0",
+		LogLevel:      "info",
+		AllowedLabels: []string{"mongodb-atlas-local=container"},
+	}
+}
+
+// Load builds a Config from ATLAS_LOCAL_* environment variables and, if
+// path is non-empty, overrides it with the contents of a YAML file.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	cfg.applyEnv()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) applyEnv() {
+	if v := os.Getenv("ATLAS_LOCAL_DEFAULT_IMAGE"); v != "" {
+		c.DefaultImage = v
+	}
+	if v := os.Getenv("ATLAS_LOCAL_DEFAULT_TAG"); v != "" {
+		c.DefaultTag = v
+	}
+	if v := os.Getenv("ATLAS_LOCAL_DEFAULT_USERNAME"); v != "" {
+		c.DefaultUsername = v
+	}
+	if v := os.Getenv("ATLAS_LOCAL_DEFAULT_PASSWORD"); v != "" {
+		c.DefaultPassword = v
+	}
+	if v := os.Getenv("ATLAS_LOCAL_HOST_BIND_IP"); v != "" {
+		c.HostBindIP = v
+	}
+	if v := os.Getenv("ATLAS_LOCAL_LOG_LEVEL"); v != "" {
+		c.LogLevel = v
+	}
+	if v := os.Getenv("ATLAS_LOCAL_ALLOWED_LABELS"); v != "" {
+		c.AllowedLabels = strings.Split(v, ",")
+	}
+	if v := os.Getenv("ATLAS_LOCAL_ALLOWED_TAGS"); v != "" {
+		c.AllowedTags = strings.Split(v, ",")
+	}
+}
+
+// TagAllowed reports whether tag may be requested by a client, given the
+// configured allow-list. An empty allow-list permits any tag.
+func (c *Config) TagAllowed(tag string) bool {
+	if len(c.AllowedTags) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowedTags {
+		if allowed == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Image returns the fully-qualified default image reference (image:tag).
+func (c *Config) Image() string {
+	return c.DefaultImage + ":" + c.DefaultTag
+}
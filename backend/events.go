@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/client"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+const eventsHeartbeatInterval = 30 * time.Second
+
+var eventsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// containerEvent is the JSON frame forwarded to connected UI clients for
+// every relevant Docker daemon event.
+type containerEvent struct {
+	Type   string `json:"type"`
+	Action string `json:"action"`
+	Id     string `json:"id"`
+	Status string `json:"status,omitempty"`
+}
+
+var relevantEventActions = map[string]bool{
+	"create":        true,
+	"start":         true,
+	"die":           true,
+	"destroy":       true,
+	"health_status": true,
+}
+
+// streamEvents upgrades the request to a WebSocket and forwards Atlas
+// Local container lifecycle events to the client as JSON frames until it
+// disconnects or the request context is cancelled. A 30s heartbeat ping
+// keeps idle connections (and any intermediate proxies) alive.
+func streamEvents(ctx echo.Context) error {
+	conn, err := eventsUpgrader.Upgrade(ctx.Response(), ctx.Request(), nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+
+	eventsCtx, cancel := context.WithCancel(ctx.Request().Context())
+	defer cancel()
+
+	messages, errs := c.Events(eventsCtx, types.EventsOptions{
+		Filters: allowedLabelFilters(),
+	})
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-eventsCtx.Done():
+			return nil
+		case err := <-errs:
+			if err != nil {
+				logger.Warnf("events stream closed: %v", err)
+			}
+			return nil
+		case msg := <-messages:
+			if msg.Type != events.ContainerEventType || !relevantEventActions[string(msg.Action)] {
+				continue
+			}
+			frame := containerEvent{
+				Type:   string(msg.Type),
+				Action: string(msg.Action),
+				Id:     msg.Actor.ID,
+				Status: msg.Actor.Attributes["health_status"],
+			}
+			if err := conn.WriteJSON(frame); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+const healthCacheTTL = 5 * time.Second
+const healthCheckTimeout = 3 * time.Second
+
+// healthResult is what listContainers reports for a single container's
+// MongoDB readiness, as opposed to the raw Docker container status.
+type healthResult struct {
+	Healthy       bool
+	ServerVersion string
+	LastError     string
+}
+
+type healthCacheEntry struct {
+	result    healthResult
+	expiresAt time.Time
+}
+
+var (
+	healthCacheMu sync.Mutex
+	healthCache   = map[string]healthCacheEntry{}
+)
+
+// containerHealth reports MongoDB readiness for containerID, running a
+// mongosh probe inside the container and caching the outcome for
+// healthCacheTTL so repeated UI polls don't hammer the daemon.
+func containerHealth(c *client.Client, containerID string) healthResult {
+	healthCacheMu.Lock()
+	if entry, ok := healthCache[containerID]; ok && time.Now().Before(entry.expiresAt) {
+		healthCacheMu.Unlock()
+		return entry.result
+	}
+	healthCacheMu.Unlock()
+
+	result := probeMongo(c, containerID)
+
+	healthCacheMu.Lock()
+	healthCache[containerID] = healthCacheEntry{result: result, expiresAt: time.Now().Add(healthCacheTTL)}
+	healthCacheMu.Unlock()
+
+	return result
+}
+
+// probeMongo runs `db.hello()` and `db.version()` inside the container via
+// ContainerExec and reports whether the node answered as a writable
+// primary. A failed or timed-out exec is reported as unhealthy rather
+// than returned as an error, since it's expected while the container is
+// still starting up.
+func probeMongo(c *client.Client, containerID string) healthResult {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	exec, err := c.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		Cmd:          []string{"mongosh", "--quiet", "--eval", "db.hello().isWritablePrimary + ' ' + db.version()"},
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return healthResult{LastError: err.Error()}
+	}
+
+	attach, err := c.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return healthResult{LastError: err.Error()}
+	}
+	defer attach.Close()
+
+	// mongosh's output arrives multiplexed (8-byte stream header per chunk)
+	// since the exec wasn't created with a TTY; demux before parsing it.
+	var out bytes.Buffer
+	if _, err := stdcopy.StdCopy(&out, &out, attach.Reader); err != nil {
+		return healthResult{LastError: err.Error()}
+	}
+
+	inspect, err := c.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return healthResult{LastError: err.Error()}
+	}
+	if inspect.ExitCode != 0 {
+		return healthResult{LastError: strings.TrimSpace(out.String())}
+	}
+
+	fields := strings.Fields(out.String())
+	if len(fields) < 2 {
+		return healthResult{LastError: strings.TrimSpace(out.String())}
+	}
+
+	return healthResult{
+		Healthy:       fields[0] == "true",
+		ServerVersion: fields[1],
+	}
+}
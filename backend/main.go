@@ -4,16 +4,22 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
+	"github.com/fmenezes/mongodb-atlas-local-extension/backend/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/sirupsen/logrus"
@@ -21,15 +27,32 @@ import (
 
 var logger = logrus.New()
 
+// cfg holds the backend settings loaded at startup from ATLAS_LOCAL_* env
+// vars and an optional --config file. Handlers read it directly, the same
+// way they share the package-level logger.
+var cfg = config.Default()
+
 func main() {
 	var socketPath string
+	var configPath string
 	flag.StringVar(&socketPath, "socket", "/run/guest-services/backend.sock", "Unix domain socket to listen on")
+	flag.StringVar(&configPath, "config", "", "Path to a YAML file overriding ATLAS_LOCAL_* env vars")
 	flag.Parse()
 
+	loaded, err := config.Load(configPath)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	cfg = loaded
+
 	_ = os.RemoveAll(socketPath)
 
 	logger.SetOutput(os.Stdout)
 
+	if level, err := logrus.ParseLevel(cfg.LogLevel); err == nil {
+		logger.SetLevel(level)
+	}
+
 	logMiddleware := middleware.LoggerWithConfig(middleware.LoggerConfig{
 		Skipper: middleware.DefaultSkipper,
 		Format: `{"time":"${time_rfc3339_nano}","id":"${id}",` +
@@ -54,6 +77,16 @@ func main() {
 
 	router.GET("/containers", listContainers)
 	router.POST("/containers", createContainer)
+	router.POST("/containers/:id/start", startContainer)
+	router.POST("/containers/:id/stop", stopContainer)
+	router.POST("/containers/:id/restart", restartContainer)
+	router.DELETE("/containers/:id", deleteContainer)
+	router.GET("/containers/:id/logs", containerLogs)
+	router.DELETE("/volumes/:name", deleteVolume)
+	router.POST("/compose", composeStack)
+	router.GET("/compose/:project", listComposeStack)
+	router.DELETE("/compose/:project", deleteComposeStack)
+	router.GET("/events", streamEvents)
 
 	logger.Fatal(router.Start(startURL))
 }
@@ -62,6 +95,21 @@ func listen(path string) (net.Listener, error) {
 	return net.Listen("unix", path)
 }
 
+// allowedLabelFilters builds the Docker label filter used when listing
+// containers from cfg.AllowedLabels, falling back to the built-in
+// "mongodb-atlas-local=container" label if none are configured.
+func allowedLabelFilters() filters.Args {
+	args := filters.NewArgs()
+	labels := cfg.AllowedLabels
+	if len(labels) == 0 {
+		labels = []string{"mongodb-atlas-local=container"}
+	}
+	for _, label := range labels {
+		args.Add("label", label)
+	}
+	return args
+}
+
 func listContainers(ctx echo.Context) error {
 	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
@@ -71,7 +119,7 @@ func listContainers(ctx echo.Context) error {
 	containerCtx := context.Background()
 
 	containers, err := c.ContainerList(containerCtx, types.ContainerListOptions{
-		Filters: filters.NewArgs(filters.Arg("label", "mongodb-atlas-local=container")),
+		Filters: allowedLabelFilters(),
 	})
 	if err != nil {
 		return err
@@ -125,12 +173,18 @@ func listContainers(ctx echo.Context) error {
 			}
 		}
 
+		health := containerHealth(c, container.ID)
+
 		response = append(response, ListContainersResponse{
 			Id:               container.ID,
 			Name:             name,
 			Status:           container.Status,
 			Version:          container.Labels["version"],
 			ConnectionString: connectionString,
+			DataVolume:       dataVolumeName(inspect.Mounts),
+			Healthy:          health.Healthy,
+			ServerVersion:    health.ServerVersion,
+			LastError:        health.LastError,
 		})
 	}
 
@@ -148,34 +202,63 @@ func createContainer(ctx echo.Context) error {
 		return err
 	}
 
+	if body.ReplicaSet != nil {
+		return createReplicaSet(ctx, c, body)
+	}
+
+	if body.Tag != "" && !cfg.TagAllowed(body.Tag) {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("image tag %q is not allowed", body.Tag))
+	}
+
+	image := cfg.DefaultImage
+	tag := cfg.DefaultTag
+	if body.Tag != "" {
+		tag = body.Tag
+	}
+
 	port := "0"
 	if body.Port != "" {
 		port = body.Port
 	}
 
-	env := []string{}
+	username := cfg.DefaultUsername
 	if body.Username != "" {
-		env = append(env, fmt.Sprintf("MONGODB_INITDB_ROOT_USERNAME=%s", body.Username))
+		username = body.Username
 	}
+	password := cfg.DefaultPassword
 	if body.Password != "" {
-		env = append(env, fmt.Sprintf("MONGODB_INITDB_ROOT_PASSWORD=%s", body.Password))
+		password = body.Password
+	}
+
+	env := []string{}
+	if username != "" {
+		env = append(env, fmt.Sprintf("MONGODB_INITDB_ROOT_USERNAME=%s", username))
+	}
+	if password != "" {
+		env = append(env, fmt.Sprintf("MONGODB_INITDB_ROOT_PASSWORD=%s", password))
 	}
 
 	containerCtx := context.Background()
 
+	mounts, err := dataMounts(containerCtx, c, body)
+	if err != nil {
+		return err
+	}
+
 	result, err := c.ContainerCreate(containerCtx, &container.Config{
 		Hostname: body.Name,
-		Image:    "mongodb/mongodb-atlas-local",
+		Image:    fmt.Sprintf("%s:%s", image, tag),
 		Env:      env,
 	}, &container.HostConfig{
 		PortBindings: nat.PortMap{
 			"27017/tcp": []nat.PortBinding{
 				{
-					HostIP:   "0.0.0.0",
+					HostIP:   cfg.HostBindIP,
 					HostPort: port,
 				},
 			},
 		},
+		Mounts: mounts,
 	}, nil, nil, body.Name)
 	if err != nil {
 		return err
@@ -189,11 +272,271 @@ func createContainer(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, map[string]string{"message": "Container created"})
 }
 
+// dataMounts builds the /data/db and /data/configdb mounts requested for a
+// container, creating the named volume if one was requested but doesn't
+// exist yet.
+func dataMounts(ctx context.Context, c *client.Client, body *CreateContainerBody) ([]mount.Mount, error) {
+	mounts := []mount.Mount{}
+
+	if body.DataVolume != "" {
+		if _, err := c.VolumeInspect(ctx, body.DataVolume); err != nil {
+			if _, err := c.VolumeCreate(ctx, volume.CreateOptions{
+				Name:   body.DataVolume,
+				Labels: map[string]string{"mongodb-atlas-local": "volume"},
+			}); err != nil {
+				return nil, err
+			}
+		}
+		mounts = append(mounts, mount.Mount{
+			Type:   mount.TypeVolume,
+			Source: body.DataVolume,
+			Target: "/data/db",
+		})
+	}
+
+	if body.HostPath != "" {
+		mounts = append(mounts, mount.Mount{
+			Type:   mount.TypeBind,
+			Source: body.HostPath,
+			Target: "/data/configdb",
+		})
+	}
+
+	return mounts, nil
+}
+
+// dataVolumeName returns the name of the named volume mounted at /data/db,
+// if any.
+func dataVolumeName(mounts []types.MountPoint) string {
+	for _, m := range mounts {
+		if m.Destination == "/data/db" && m.Type == mount.TypeVolume {
+			return m.Name
+		}
+	}
+	return ""
+}
+
+func deleteVolume(ctx echo.Context) error {
+	name := ctx.Param("name")
+
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+
+	containerCtx := context.Background()
+
+	inspect, err := c.VolumeInspect(containerCtx, name)
+	if err != nil {
+		return err
+	}
+	if inspect.Labels["mongodb-atlas-local"] != "volume" {
+		return echo.NewHTTPError(http.StatusNotFound, "volume not managed by this extension")
+	}
+
+	if err := c.VolumeRemove(containerCtx, name, true); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Volume removed"})
+}
+
+// isManagedContainer reports whether labels match one of cfg.AllowedLabels
+// (the same allow-list used to scope GET /containers), so lifecycle
+// endpoints can't be pointed at unrelated containers on the host.
+func isManagedContainer(labels map[string]string) bool {
+	allowed := cfg.AllowedLabels
+	if len(allowed) == 0 {
+		allowed = []string{"mongodb-atlas-local=container"}
+	}
+	for _, kv := range allowed {
+		key, value, found := strings.Cut(kv, "=")
+		if !found {
+			continue
+		}
+		if labels[key] == value {
+			return true
+		}
+	}
+	return false
+}
+
+// requireManagedContainer inspects id and fails with 404 unless it carries
+// one of the allow-listed labels, so callers can't act on containers this
+// extension didn't create.
+func requireManagedContainer(ctx context.Context, c *client.Client, id string) (types.ContainerJSON, error) {
+	inspect, err := c.ContainerInspect(ctx, id)
+	if err != nil {
+		return inspect, err
+	}
+	if !isManagedContainer(inspect.Config.Labels) {
+		return inspect, echo.NewHTTPError(http.StatusNotFound, "container not managed by this extension")
+	}
+	return inspect, nil
+}
+
+func startContainer(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+
+	containerCtx := context.Background()
+	if _, err := requireManagedContainer(containerCtx, c, id); err != nil {
+		return err
+	}
+
+	if err := c.ContainerStart(containerCtx, id, types.ContainerStartOptions{}); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Container started"})
+}
+
+func stopContainer(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+
+	containerCtx := context.Background()
+	if _, err := requireManagedContainer(containerCtx, c, id); err != nil {
+		return err
+	}
+
+	if err := c.ContainerStop(containerCtx, id, container.StopOptions{}); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Container stopped"})
+}
+
+func restartContainer(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+
+	containerCtx := context.Background()
+	if _, err := requireManagedContainer(containerCtx, c, id); err != nil {
+		return err
+	}
+
+	if err := c.ContainerRestart(containerCtx, id, container.StopOptions{}); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Container restarted"})
+}
+
+func deleteContainer(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	force, _ := strconv.ParseBool(ctx.QueryParam("force"))
+	purgeVolume, _ := strconv.ParseBool(ctx.QueryParam("purgeVolume"))
+
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+
+	containerCtx := context.Background()
+
+	inspect, err := requireManagedContainer(containerCtx, c, id)
+	if err != nil {
+		return err
+	}
+
+	volumeName := ""
+	if purgeVolume {
+		volumeName = dataVolumeName(inspect.Mounts)
+	}
+
+	if err := c.ContainerRemove(containerCtx, id, types.ContainerRemoveOptions{Force: force}); err != nil {
+		return err
+	}
+
+	if volumeName != "" {
+		if err := c.VolumeRemove(containerCtx, volumeName, true); err != nil {
+			return err
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Container removed"})
+}
+
+func containerLogs(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	follow, _ := strconv.ParseBool(ctx.QueryParam("follow"))
+
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+
+	containerCtx := context.Background()
+	if _, err := requireManagedContainer(containerCtx, c, id); err != nil {
+		return err
+	}
+
+	reader, err := c.ContainerLogs(containerCtx, id, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     follow,
+		Timestamps: false,
+	})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	ctx.Response().Header().Set(echo.HeaderContentType, "application/octet-stream")
+	ctx.Response().WriteHeader(http.StatusOK)
+
+	out := flushWriter{ctx.Response()}
+	if _, err := stdcopy.StdCopy(out, out, reader); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// flushWriter flushes the underlying echo.Response after every write so
+// `follow=true` log streams reach the client chunk by chunk instead of
+// being buffered until the handler returns.
+type flushWriter struct {
+	response *echo.Response
+}
+
+func (w flushWriter) Write(p []byte) (int, error) {
+	n, err := w.response.Write(p)
+	w.response.Flush()
+	return n, err
+}
+
 type CreateContainerBody struct {
-	Name     string `json:"name,omitempty"`
-	Port     string `json:"port,omitempty"`
-	Username string `json:"username,omitempty"`
-	Password string `json:"password,omitempty"`
+	Name       string            `json:"name,omitempty"`
+	Port       string            `json:"port,omitempty"`
+	Username   string            `json:"username,omitempty"`
+	Password   string            `json:"password,omitempty"`
+	Tag        string            `json:"tag,omitempty"`
+	ReplicaSet *ReplicaSetConfig `json:"replicaSet,omitempty"`
+	DataVolume string            `json:"dataVolume,omitempty"`
+	HostPath   string            `json:"hostPath,omitempty"`
+}
+
+// ReplicaSetConfig describes a multi-node Atlas Local replica set to provision
+// instead of a single standalone container.
+type ReplicaSetConfig struct {
+	Name    string `json:"name,omitempty"`
+	Members int    `json:"members,omitempty"`
+	KeyFile string `json:"keyFile,omitempty"`
 }
 
 type ListContainersResponse struct {
@@ -203,4 +546,8 @@ type ListContainersResponse struct {
 	Status           string `json:"status,omitempty"`
 	Version          string `json:"version,omitempty"`
 	ConnectionString string `json:"connectionString,omitempty"`
+	DataVolume       string `json:"dataVolume,omitempty"`
+	Healthy          bool   `json:"healthy"`
+	ServerVersion    string `json:"serverVersion,omitempty"`
+	LastError        string `json:"lastError,omitempty"`
 }
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
+	"github.com/labstack/echo/v4"
+)
+
+const replicaSetReadyTimeout = 2 * time.Minute
+
+// createReplicaSet provisions a multi-node Atlas Local replica set on a
+// dedicated Docker network and initiates it once every member is reachable.
+// Any failure after containers have started rolls back everything it created.
+func createReplicaSet(ctx echo.Context, c *client.Client, body *CreateContainerBody) error {
+	rs := body.ReplicaSet
+	if rs.Name == "" {
+		return fmt.Errorf("replicaSet.name is required")
+	}
+	if rs.Members <= 0 {
+		rs.Members = 3
+	}
+
+	if body.Tag != "" && !cfg.TagAllowed(body.Tag) {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("image tag %q is not allowed", body.Tag))
+	}
+	image := cfg.DefaultImage
+	tag := cfg.DefaultTag
+	if body.Tag != "" {
+		tag = body.Tag
+	}
+
+	containerCtx := context.Background()
+	networkName := fmt.Sprintf("%s-network", rs.Name)
+
+	networkID, networkCreated, err := ensureReplicaSetNetwork(containerCtx, c, networkName)
+	if err != nil {
+		return err
+	}
+
+	var keyFilePath string
+	if rs.KeyFile != "" {
+		keyFilePath, err = writeKeyFile(rs.Name, rs.KeyFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	memberIDs := []string{}
+	rollback := func() {
+		for _, id := range memberIDs {
+			_ = c.ContainerRemove(containerCtx, id, types.ContainerRemoveOptions{Force: true})
+		}
+		if networkCreated {
+			_ = c.NetworkRemove(containerCtx, networkID)
+		}
+		if keyFilePath != "" {
+			_ = os.RemoveAll(filepath.Dir(keyFilePath))
+		}
+	}
+
+	basePort := 0
+	if body.Port != "" {
+		basePort, _ = strconv.Atoi(body.Port)
+	}
+
+	for i := 0; i < rs.Members; i++ {
+		memberName := fmt.Sprintf("%s-%d", rs.Name, i)
+
+		env := []string{
+			fmt.Sprintf("MONGODB_REPLICA_SET_MODE=%s", rs.Name),
+		}
+		if body.Username != "" {
+			env = append(env, fmt.Sprintf("MONGODB_INITDB_ROOT_USERNAME=%s", body.Username))
+		}
+		if body.Password != "" {
+			env = append(env, fmt.Sprintf("MONGODB_INITDB_ROOT_PASSWORD=%s", body.Password))
+		}
+
+		hostPort := "0"
+		if basePort > 0 {
+			hostPort = strconv.Itoa(basePort + i)
+		}
+
+		cmd := []string{"--replSet", rs.Name, "--bind_ip_all"}
+
+		mounts := []mount.Mount{}
+		if keyFilePath != "" {
+			cmd = append(cmd, "--keyFile", "/data/configdb/keyfile")
+			mounts = append(mounts, mount.Mount{
+				Type:     mount.TypeBind,
+				Source:   keyFilePath,
+				Target:   "/data/configdb/keyfile",
+				ReadOnly: true,
+			})
+		}
+
+		result, err := c.ContainerCreate(containerCtx, &container.Config{
+			Hostname: memberName,
+			Image:    fmt.Sprintf("%s:%s", image, tag),
+			Env:      env,
+			Cmd:      cmd,
+		}, &container.HostConfig{
+			Mounts: mounts,
+			PortBindings: nat.PortMap{
+				"27017/tcp": []nat.PortBinding{
+					{
+						HostIP:   cfg.HostBindIP,
+						HostPort: hostPort,
+					},
+				},
+			},
+		}, &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				networkName: {NetworkID: networkID},
+			},
+		}, nil, memberName)
+		if err != nil {
+			rollback()
+			return err
+		}
+		memberIDs = append(memberIDs, result.ID)
+
+		if err := c.ContainerStart(containerCtx, result.ID, types.ContainerStartOptions{}); err != nil {
+			rollback()
+			return err
+		}
+	}
+
+	for _, id := range memberIDs {
+		if err := waitForMongoReady(containerCtx, c, id, replicaSetReadyTimeout); err != nil {
+			rollback()
+			return err
+		}
+	}
+
+	members := make([]string, rs.Members)
+	for i := range members {
+		members[i] = fmt.Sprintf(`{ _id: %d, host: "%s-%d:27017" }`, i, rs.Name, i)
+	}
+	initiateCmd := fmt.Sprintf("rs.initiate({ _id: %q, members: [%s] })", rs.Name, joinMembers(members))
+
+	if err := mongoExec(containerCtx, c, memberIDs[0], initiateCmd); err != nil {
+		rollback()
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"message": "Replica set created"})
+}
+
+// ensureReplicaSetNetwork returns the ID of the named network, creating it
+// if it doesn't exist yet. The second return value reports whether this
+// call created the network, so a caller rolling back a failed request
+// only removes networks it actually created.
+func ensureReplicaSetNetwork(ctx context.Context, c *client.Client, name string) (string, bool, error) {
+	networks, err := c.NetworkList(ctx, types.NetworkListOptions{
+		Filters: filters.NewArgs(filters.Arg("name", name)),
+	})
+	if err != nil {
+		return "", false, err
+	}
+	for _, n := range networks {
+		if n.Name == name {
+			return n.ID, false, nil
+		}
+	}
+
+	created, err := c.NetworkCreate(ctx, name, types.NetworkCreate{
+		Labels: map[string]string{"mongodb-atlas-local": "replicaset"},
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return created.ID, true, nil
+}
+
+func writeKeyFile(rsName string, contents string) (string, error) {
+	dir, err := os.MkdirTemp("", fmt.Sprintf("mongodb-atlas-local-%s-", rsName))
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "keyfile")
+	if err := os.WriteFile(path, []byte(contents), 0o400); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// waitForMongoReady polls a member with a mongosh ping until it responds or
+// the timeout elapses.
+func waitForMongoReady(ctx context.Context, c *client.Client, containerID string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for time.Now().Before(deadline) {
+		if lastErr = mongoExec(ctx, c, containerID, "db.runCommand({ ping: 1 })"); lastErr == nil {
+			return nil
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return fmt.Errorf("container %s did not become ready: %w", containerID[:12], lastErr)
+}
+
+// mongoExec runs a mongosh --eval command inside a running container and
+// returns an error if the exec itself or the evaluated script fails.
+func mongoExec(ctx context.Context, c *client.Client, containerID string, script string) error {
+	exec, err := c.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		Cmd:          []string{"mongosh", "--quiet", "--eval", script},
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	attach, err := c.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return err
+	}
+	defer attach.Close()
+
+	// mongosh's output arrives multiplexed (8-byte stream header per
+	// chunk) since the exec wasn't created with a TTY; demux before using
+	// it in an error message.
+	var out bytes.Buffer
+	if _, err := stdcopy.StdCopy(&out, &out, attach.Reader); err != nil {
+		return err
+	}
+
+	inspect, err := c.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return err
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("mongosh exec failed: %s", out.String())
+	}
+	return nil
+}
+
+func joinMembers(members []string) string {
+	result := ""
+	for i, m := range members {
+		if i > 0 {
+			result += ", "
+		}
+		result += m
+	}
+	return result
+}